@@ -0,0 +1,165 @@
+package composegen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/client"
+	"gopkg.in/yaml.v2"
+)
+
+// ValueDiff is a single service key whose value differs between an
+// existing compose file and the live state it was diffed against.
+// Old or New is nil when the key is only present on one side.
+type ValueDiff struct {
+	Old interface{}
+	New interface{}
+}
+
+// Diff is the structural difference between an existing compose file
+// and the live state of the host it describes.
+type Diff struct {
+	AddedServices   []string
+	RemovedServices []string
+	ChangedServices map[string]map[string]ValueDiff
+}
+
+// DiffAgainst parses an on-disk compose file, generates the current
+// live state for the containers selected by opts, and returns the
+// structured difference between them: services present on the host
+// but missing from existingYAML, services in existingYAML with no
+// container on the host, and per-service keys whose value drifted.
+func DiffAgainst(existingYAML []byte, cli *client.Client, opts Options) (*Diff, error) {
+	var existing Config
+	if err := yaml.Unmarshal(existingYAML, &existing); err != nil {
+		return nil, fmt.Errorf("error parsing existing compose file: %v", err)
+	}
+
+	set, err := GenerateContainerSet(cli, opts)
+	if err != nil {
+		return nil, err
+	}
+	live := buildComposeConfig(set)
+
+	return diffConfigs(existing, live), nil
+}
+
+// diffConfigs normalizes both sides through the same Config shape and
+// deep-compares them service by service.
+func diffConfigs(existing, live Config) *Diff {
+	diff := &Diff{ChangedServices: map[string]map[string]ValueDiff{}}
+
+	for name := range live.Services {
+		if _, ok := existing.Services[name]; !ok {
+			diff.AddedServices = append(diff.AddedServices, name)
+		}
+	}
+	for name := range existing.Services {
+		if _, ok := live.Services[name]; !ok {
+			diff.RemovedServices = append(diff.RemovedServices, name)
+		}
+	}
+	sort.Strings(diff.AddedServices)
+	sort.Strings(diff.RemovedServices)
+
+	for name, liveValues := range live.Services {
+		existingValues, ok := existing.Services[name]
+		if !ok {
+			continue
+		}
+		if changed := diffServiceValues(existingValues, liveValues); len(changed) > 0 {
+			diff.ChangedServices[name] = changed
+		}
+	}
+
+	return diff
+}
+
+// diffServiceValues compares a single service's old and new key/value
+// maps, reporting every key whose value differs or that only exists
+// on one side.
+func diffServiceValues(old, new map[string]interface{}) map[string]ValueDiff {
+	changed := map[string]ValueDiff{}
+
+	keys := map[string]struct{}{}
+	for k := range old {
+		keys[k] = struct{}{}
+	}
+	for k := range new {
+		keys[k] = struct{}{}
+	}
+
+	for k := range keys {
+		oldVal, oldOk := old[k]
+		newVal, newOk := new[k]
+		switch {
+		case !oldOk:
+			changed[k] = ValueDiff{New: newVal}
+		case !newOk:
+			changed[k] = ValueDiff{Old: oldVal}
+		case !valuesEqual(oldVal, newVal):
+			changed[k] = ValueDiff{Old: oldVal, New: newVal}
+		}
+	}
+
+	return changed
+}
+
+// valuesEqual compares two compose values for structural equality.
+// Values parsed from YAML and values built in-process don't share the
+// same concrete map/slice types (yaml.v2 unmarshals nested objects as
+// map[interface{}]interface{}), so reflect.DeepEqual would report
+// spurious differences; re-marshaling both sides to YAML (which
+// yaml.v2 emits with sorted map keys) gives a stable, type-agnostic
+// comparison instead.
+func valuesEqual(a, b interface{}) bool {
+	ay, aerr := yaml.Marshal(a)
+	by, berr := yaml.Marshal(b)
+	if aerr != nil || berr != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return string(ay) == string(by)
+}
+
+// String renders the Diff in a unified-diff-like style: added and
+// removed services first, then each changed service's drifted keys.
+func (d *Diff) String() string {
+	var b strings.Builder
+
+	for _, name := range d.AddedServices {
+		fmt.Fprintf(&b, "+++ %s (new service)\n", name)
+	}
+	for _, name := range d.RemovedServices {
+		fmt.Fprintf(&b, "--- %s (removed service)\n", name)
+	}
+
+	names := make([]string, 0, len(d.ChangedServices))
+	for name := range d.ChangedServices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(&b, "@@ %s @@\n", name)
+
+		keys := make([]string, 0, len(d.ChangedServices[name]))
+		for key := range d.ChangedServices[name] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			vd := d.ChangedServices[name][key]
+			if vd.Old != nil {
+				fmt.Fprintf(&b, "-%s: %v\n", key, vd.Old)
+			}
+			if vd.New != nil {
+				fmt.Fprintf(&b, "+%s: %v\n", key, vd.New)
+			}
+		}
+	}
+
+	return b.String()
+}
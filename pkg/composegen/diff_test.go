@@ -0,0 +1,92 @@
+package composegen
+
+import "testing"
+
+func TestValuesEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b interface{}
+		want bool
+	}{
+		{
+			name: "identical strings",
+			a:    "nginx:latest",
+			b:    "nginx:latest",
+			want: true,
+		},
+		{
+			name: "different strings",
+			a:    "nginx:latest",
+			b:    "nginx:1.25",
+			want: false,
+		},
+		{
+			name: "map[string]interface{} vs map[interface{}]interface{}",
+			a:    map[string]interface{}{"driver": "json-file"},
+			b:    map[interface{}]interface{}{"driver": "json-file"},
+			want: true,
+		},
+		{
+			name: "slice order matters",
+			a:    []string{"80:80", "443:443"},
+			b:    []string{"443:443", "80:80"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := valuesEqual(tt.a, tt.b); got != tt.want {
+				t.Errorf("valuesEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffConfigs(t *testing.T) {
+	existing := Config{
+		Services: map[string]map[string]interface{}{
+			"web": {"image": "nginx:1.24"},
+			"old": {"image": "redis:6"},
+		},
+	}
+	live := Config{
+		Services: map[string]map[string]interface{}{
+			"web": {"image": "nginx:1.25"},
+			"new": {"image": "postgres:16"},
+		},
+	}
+
+	diff := diffConfigs(existing, live)
+
+	if want := []string{"new"}; !equalStrings(diff.AddedServices, want) {
+		t.Errorf("AddedServices = %v, want %v", diff.AddedServices, want)
+	}
+	if want := []string{"old"}; !equalStrings(diff.RemovedServices, want) {
+		t.Errorf("RemovedServices = %v, want %v", diff.RemovedServices, want)
+	}
+
+	changed, ok := diff.ChangedServices["web"]
+	if !ok {
+		t.Fatalf("ChangedServices missing %q", "web")
+	}
+	imageDiff, ok := changed["image"]
+	if !ok {
+		t.Fatalf("ChangedServices[%q] missing %q", "web", "image")
+	}
+	if imageDiff.Old != "nginx:1.24" || imageDiff.New != "nginx:1.25" {
+		t.Errorf("image ValueDiff = %+v, want Old=nginx:1.24 New=nginx:1.25", imageDiff)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
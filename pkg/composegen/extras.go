@@ -0,0 +1,125 @@
+package composegen
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+// getHealthcheck normalizes Config.Healthcheck, returning nil when the
+// image defines no healthcheck.
+func getHealthcheck(cattrs types.ContainerJSON) *Healthcheck {
+	hc := cattrs.Config.Healthcheck
+	if hc == nil || len(hc.Test) == 0 {
+		return nil
+	}
+	return &Healthcheck{
+		Test:        hc.Test,
+		Interval:    hc.Interval,
+		Timeout:     hc.Timeout,
+		Retries:     hc.Retries,
+		StartPeriod: hc.StartPeriod,
+	}
+}
+
+// getUlimits normalizes HostConfig.Ulimits.
+func getUlimits(cattrs types.ContainerJSON) []Ulimit {
+	var ulimits []Ulimit
+	for _, u := range cattrs.HostConfig.Ulimits {
+		ulimits = append(ulimits, Ulimit{Name: u.Name, Soft: u.Soft, Hard: u.Hard})
+	}
+	return ulimits
+}
+
+// getDevices normalizes HostConfig.Devices.
+func getDevices(cattrs types.ContainerJSON) []DeviceMapping {
+	var devices []DeviceMapping
+	for _, d := range cattrs.HostConfig.Devices {
+		devices = append(devices, DeviceMapping{
+			PathOnHost:        d.PathOnHost,
+			PathInContainer:   d.PathInContainer,
+			CgroupPermissions: d.CgroupPermissions,
+		})
+	}
+	return devices
+}
+
+// getLogging normalizes HostConfig.LogConfig, returning nil when the
+// container uses the daemon's default driver with no options.
+func getLogging(cattrs types.ContainerJSON) *LoggingConfig {
+	logConfig := cattrs.HostConfig.LogConfig
+	if logConfig.Type == "" && len(logConfig.Config) == 0 {
+		return nil
+	}
+	return &LoggingConfig{Driver: logConfig.Type, Options: logConfig.Config}
+}
+
+// getTmpfs normalizes HostConfig.Tmpfs into compose's "path[:options]"
+// short syntax.
+func getTmpfs(cattrs types.ContainerJSON) []string {
+	var tmpfs []string
+	for path, opts := range cattrs.HostConfig.Tmpfs {
+		if opts != "" {
+			tmpfs = append(tmpfs, path+":"+opts)
+		} else {
+			tmpfs = append(tmpfs, path)
+		}
+	}
+	sort.Strings(tmpfs)
+	return tmpfs
+}
+
+// getDependsOn infers service dependencies from legacy container
+// links and from shared network/IPC/PID namespaces expressed as
+// "container:<name-or-id>".
+func getDependsOn(cattrs types.ContainerJSON) []string {
+	seen := map[string]struct{}{}
+	var dependsOn []string
+	add := func(name string) {
+		if name == "" {
+			return
+		}
+		if _, ok := seen[name]; ok {
+			return
+		}
+		seen[name] = struct{}{}
+		dependsOn = append(dependsOn, name)
+	}
+
+	for _, link := range cattrs.HostConfig.Links {
+		add(linkedContainerName(link))
+	}
+	if name, ok := containerModeRef(string(cattrs.HostConfig.NetworkMode)); ok {
+		add(name)
+	}
+	if name, ok := containerModeRef(string(cattrs.HostConfig.IpcMode)); ok {
+		add(name)
+	}
+	if name, ok := containerModeRef(string(cattrs.HostConfig.PidMode)); ok {
+		add(name)
+	}
+
+	return dependsOn
+}
+
+// linkedContainerName extracts the linked container's name out of a
+// HostConfig.Links entry, which has the form
+// "/other-container:/this-container/alias".
+func linkedContainerName(link string) string {
+	target := link
+	if idx := strings.Index(link, ":"); idx != -1 {
+		target = link[:idx]
+	}
+	return strings.TrimPrefix(target, "/")
+}
+
+// containerModeRef reports the container name referenced by a
+// "container:<name-or-id>" network/IPC/PID mode string.
+func containerModeRef(mode string) (string, bool) {
+	const prefix = "container:"
+	if !strings.HasPrefix(mode, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(mode, prefix), true
+}
@@ -0,0 +1,89 @@
+package composegen
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestGetDependsOn(t *testing.T) {
+	tests := []struct {
+		name   string
+		cattrs types.ContainerJSON
+		want   []string
+	}{
+		{
+			name: "legacy link",
+			cattrs: types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{
+					HostConfig: &container.HostConfig{
+						Links: []string{"/db:/web/db"},
+					},
+				},
+			},
+			want: []string{"db"},
+		},
+		{
+			name: "shared network namespace",
+			cattrs: types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{
+					HostConfig: &container.HostConfig{
+						NetworkMode: "container:net-ns",
+					},
+				},
+			},
+			want: []string{"net-ns"},
+		},
+		{
+			name: "dedupes repeated references",
+			cattrs: types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{
+					HostConfig: &container.HostConfig{
+						Links:       []string{"/db:/web/db"},
+						NetworkMode: "container:db",
+						IpcMode:     "container:db",
+					},
+				},
+			},
+			want: []string{"db"},
+		},
+		{
+			name: "no references",
+			cattrs: types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{
+					HostConfig: &container.HostConfig{},
+				},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getDependsOn(tt.cattrs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getDependsOn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTmpfs(t *testing.T) {
+	cattrs := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			HostConfig: &container.HostConfig{
+				Tmpfs: map[string]string{
+					"/run":     "",
+					"/tmp":     "rw,noexec",
+					"/run/app": "size=64m",
+				},
+			},
+		},
+	}
+
+	want := []string{"/run", "/run/app:size=64m", "/tmp:rw,noexec"}
+	if got := getTmpfs(cattrs); !reflect.DeepEqual(got, want) {
+		t.Errorf("getTmpfs() = %v, want %v (output must be sorted for stable diffs)", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package composegen
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/client"
+)
+
+// Transformer mutates a single service's compose value before it is
+// written out. It runs after a service's raw values are built but
+// before isIgnoredValue filtering, so a Transformer can still see (and
+// drop) default values a plain reader never would. Returning ok=false
+// drops the key entirely.
+type Transformer interface {
+	Transform(service string, key string, value interface{}) (newValue interface{}, ok bool)
+}
+
+// FileProducer is an optional interface a Transformer can implement
+// to emit companion files alongside docker-compose.yml, such as the
+// .env SecretRedactor writes redacted values into.
+type FileProducer interface {
+	Files() map[string]string
+}
+
+// Generator builds compose output the same way the package-level
+// GenerateComposeFile does, but runs every registered Transformer over
+// each service's values first. Use it instead of the package-level
+// function when you need secret redaction, host path rewriting, or
+// image digest pinning.
+type Generator struct {
+	Transformers []Transformer
+}
+
+// NewGenerator builds a Generator running the given Transformers, in
+// order, over every service.
+func NewGenerator(transformers ...Transformer) *Generator {
+	return &Generator{Transformers: transformers}
+}
+
+// GenerateComposeFile inspects the containers selected by opts, runs
+// them through every registered Transformer, and renders the result
+// as one docker-compose YAML document per project (see
+// GenerateComposeFile), keyed by project name, plus whatever
+// companion files the Transformers produced (e.g. SecretRedactor's
+// ".env").
+func (g *Generator) GenerateComposeFile(cli *client.Client, opts Options) (map[string]string, error) {
+	set, err := GenerateContainerSet(cli, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var hostNetworks map[string]map[string]interface{}
+	if opts.IncludeAllContainers {
+		hostNetworks, err = generateNetworkInfo(cli)
+		if err != nil {
+			return nil, fmt.Errorf("error generating network info: %v", err)
+		}
+	}
+
+	files := map[string]string{}
+	for project, projectSet := range groupByProject(set) {
+		config := buildComposeConfigSkeleton(projectSet)
+		config.Services = g.transformServices(projectSet)
+		if hostNetworks != nil {
+			config.Networks = hostNetworks
+		}
+
+		out, err := render(config)
+		if err != nil {
+			return nil, err
+		}
+		files[project] = out
+	}
+
+	for _, t := range g.Transformers {
+		if fp, ok := t.(FileProducer); ok {
+			for name, content := range fp.Files() {
+				files[name] = content
+			}
+		}
+	}
+	return files, nil
+}
+
+func (g *Generator) transformServices(set *ContainerSet) map[string]map[string]interface{} {
+	services := map[string]map[string]interface{}{}
+	for _, spec := range set.Containers {
+		services[composeServiceKey(spec)] = g.transformServiceValues(spec)
+	}
+	return services
+}
+
+// transformServiceValues runs rawComposeValues through every
+// registered Transformer before applying isIgnoredValue filtering and
+// appending the non-transformable extras.
+func (g *Generator) transformServiceValues(spec ContainerSpec) map[string]interface{} {
+	raw := rawComposeValues(spec)
+	service := composeServiceKey(spec)
+
+	for key, value := range raw {
+		dropped := false
+		for _, t := range g.Transformers {
+			newValue, ok := t.Transform(service, key, value)
+			if !ok {
+				dropped = true
+				break
+			}
+			value = newValue
+		}
+		if dropped {
+			delete(raw, key)
+			continue
+		}
+		raw[key] = value
+	}
+
+	ct := map[string]interface{}{}
+	for key, value := range raw {
+		if !isIgnoredValue(value) {
+			ct[key] = value
+		}
+	}
+	appendComposeExtras(ct, spec)
+	return ct
+}
@@ -0,0 +1,250 @@
+package composegen
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/client"
+	"gopkg.in/yaml.v2"
+)
+
+// GenerateKubeYAML inspects the containers selected by opts and
+// renders them as Kubernetes manifests: one Deployment, one Service
+// (when the container publishes ports) and one PersistentVolumeClaim
+// per named volume, keyed by a <container>.yaml file name.
+func GenerateKubeYAML(cli *client.Client, opts Options) (map[string]string, error) {
+	set, err := GenerateContainerSet(cli, opts)
+	if err != nil {
+		return nil, err
+	}
+	return kubeRenderer{}.Render(set)
+}
+
+// kubeRenderer renders each container as its own Deployment, plus a
+// Service and PersistentVolumeClaims when applicable.
+type kubeRenderer struct{}
+
+func (kubeRenderer) Render(set *ContainerSet) (map[string]string, error) {
+	files := map[string]string{}
+	for _, spec := range set.Containers {
+		docs := []map[string]interface{}{kubeDeployment(spec)}
+
+		if svc := kubeService(spec); svc != nil {
+			docs = append(docs, svc)
+		}
+		docs = append(docs, kubeVolumeClaims(spec)...)
+
+		out, err := marshalYAMLDocuments(docs)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling manifests for %s: %v", spec.Name, err)
+		}
+		files[spec.Name+".yaml"] = out
+	}
+	return files, nil
+}
+
+func kubeDeployment(spec ContainerSpec) map[string]interface{} {
+	containerSpec := map[string]interface{}{
+		"name":  spec.Name,
+		"image": spec.Image,
+	}
+	if len(spec.Command) > 0 {
+		containerSpec["command"] = spec.Entrypoint
+		containerSpec["args"] = spec.Command
+	}
+	if spec.WorkingDir != "" {
+		containerSpec["workingDir"] = spec.WorkingDir
+	}
+	if env := kubeEnv(spec.Env); len(env) > 0 {
+		containerSpec["env"] = env
+	}
+	if ports := kubeContainerPorts(spec.Ports); len(ports) > 0 {
+		containerSpec["ports"] = ports
+	}
+	if spec.Privileged {
+		containerSpec["securityContext"] = map[string]interface{}{"privileged": true}
+	}
+
+	// restartPolicy is always "Always": Kubernetes requires it for a
+	// Deployment's pod template regardless of the container's own
+	// Docker restart policy, since Deployments exist to keep pods
+	// running indefinitely.
+	podSpec := map[string]interface{}{
+		"restartPolicy": "Always",
+		"containers":    []map[string]interface{}{containerSpec},
+	}
+	if spec.Hostname != "" {
+		podSpec["hostname"] = spec.Hostname
+	}
+	if mounts, volumes := kubeVolumeMounts(spec); len(mounts) > 0 {
+		containerSpec["volumeMounts"] = mounts
+		podSpec["volumes"] = volumes
+	}
+	return kubeDeploymentWrapper(spec, podSpec)
+}
+
+func kubeDeploymentWrapper(spec ContainerSpec, podSpec map[string]interface{}) map[string]interface{} {
+	labels := map[string]interface{}{"app": spec.Name}
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":   spec.Name,
+			"labels": labels,
+		},
+		"spec": map[string]interface{}{
+			"replicas": 1,
+			"selector": map[string]interface{}{
+				"matchLabels": labels,
+			},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": labels},
+				"spec":     podSpec,
+			},
+		},
+	}
+}
+
+func kubeEnv(env []string) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, e := range env {
+		parts := strings.SplitN(e, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		result = append(result, map[string]interface{}{"name": parts[0], "value": parts[1]})
+	}
+	return result
+}
+
+// kubePort parses a PortMapping's port string into the int Kubernetes
+// expects for Service/container port fields, so yaml.v2 doesn't quote
+// it as a string.
+func kubePort(port string) (int, bool) {
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		log.Printf("Error parsing port %q: %v", port, err)
+		return 0, false
+	}
+	return p, true
+}
+
+func kubeContainerPorts(ports []PortMapping) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, p := range ports {
+		containerPort, ok := kubePort(p.ContainerPort)
+		if !ok {
+			continue
+		}
+		result = append(result, map[string]interface{}{
+			"containerPort": containerPort,
+			"protocol":      strings.ToUpper(p.Protocol),
+		})
+	}
+	return result
+}
+
+func kubeService(spec ContainerSpec) map[string]interface{} {
+	if len(spec.Ports) == 0 {
+		return nil
+	}
+
+	var servicePorts []map[string]interface{}
+	for _, p := range spec.Ports {
+		hostPort, ok := kubePort(p.HostPort)
+		if !ok {
+			continue
+		}
+		containerPort, ok := kubePort(p.ContainerPort)
+		if !ok {
+			continue
+		}
+		servicePorts = append(servicePorts, map[string]interface{}{
+			"name":       fmt.Sprintf("%s-%s", p.Protocol, p.ContainerPort),
+			"port":       hostPort,
+			"targetPort": containerPort,
+			"protocol":   strings.ToUpper(p.Protocol),
+		})
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": spec.Name},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{"app": spec.Name},
+			"ports":    servicePorts,
+		},
+	}
+}
+
+// kubeVolumeMounts splits a ContainerSpec's volumes into the
+// volumeMounts entries a container needs and the pod-level volumes
+// they reference. Bind mounts become hostPath volumes; named volumes
+// become PVC references.
+func kubeVolumeMounts(spec ContainerSpec) ([]map[string]interface{}, []map[string]interface{}) {
+	var mounts []map[string]interface{}
+	var volumes []map[string]interface{}
+
+	for i, v := range spec.Volumes {
+		name := fmt.Sprintf("vol-%d", i)
+		mounts = append(mounts, map[string]interface{}{
+			"name":      name,
+			"mountPath": v.Destination,
+		})
+
+		if v.Type == "volume" {
+			volumes = append(volumes, map[string]interface{}{
+				"name": name,
+				"persistentVolumeClaim": map[string]interface{}{
+					"claimName": v.Source,
+				},
+			})
+		} else {
+			volumes = append(volumes, map[string]interface{}{
+				"name":     name,
+				"hostPath": map[string]interface{}{"path": v.Source},
+			})
+		}
+	}
+
+	return mounts, volumes
+}
+
+func kubeVolumeClaims(spec ContainerSpec) []map[string]interface{} {
+	var claims []map[string]interface{}
+	for _, v := range spec.Volumes {
+		if v.Type != "volume" {
+			continue
+		}
+		claims = append(claims, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "PersistentVolumeClaim",
+			"metadata":   map[string]interface{}{"name": v.Source},
+			"spec": map[string]interface{}{
+				"accessModes": []string{"ReadWriteOnce"},
+				"resources": map[string]interface{}{
+					"requests": map[string]interface{}{"storage": "1Gi"},
+				},
+			},
+		})
+	}
+	return claims
+}
+
+// marshalYAMLDocuments renders each manifest as its own YAML document
+// separated by "---", the convention kubectl expects for multi-object
+// files.
+func marshalYAMLDocuments(docs []map[string]interface{}) (string, error) {
+	var parts []string
+	for _, doc := range docs {
+		out, err := yaml.Marshal(doc)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, string(out))
+	}
+	return strings.Join(parts, "---\n"), nil
+}
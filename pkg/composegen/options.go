@@ -0,0 +1,24 @@
+package composegen
+
+// Options configures which containers are inspected and how the
+// resulting set is rendered. It is shared by every Generate* entry
+// point so new renderers don't each grow their own parameter list.
+type Options struct {
+	// IncludeAllContainers replaces the per-container network blocks
+	// with the full set of networks known to the host.
+	IncludeAllContainers bool
+
+	// ContainerFilter is a regex matched against container names; only
+	// matching containers are included when non-empty.
+	ContainerFilter string
+
+	// ProjectFilter is a regex matched against the
+	// com.docker.compose.project label; only matching containers are
+	// included when non-empty.
+	ProjectFilter string
+
+	// CreateVolumes emits named volumes for "volume" type mounts. When
+	// false, "volume" type mounts are left out and only bind mounts
+	// are emitted.
+	CreateVolumes bool
+}
@@ -0,0 +1,148 @@
+package composegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/client"
+)
+
+// GeneratePodmanQuadlet inspects the containers selected by opts and
+// renders them as Podman Quadlet unit files: one .container unit per
+// container, one .volume unit per named volume, and one .network
+// unit per network, keyed by their unit file name.
+func GeneratePodmanQuadlet(cli *client.Client, opts Options) (map[string]string, error) {
+	set, err := GenerateContainerSet(cli, opts)
+	if err != nil {
+		return nil, err
+	}
+	return quadletRenderer{}.Render(set)
+}
+
+// quadletRenderer renders each container, named volume and network as
+// its own systemd-style Quadlet unit file.
+type quadletRenderer struct{}
+
+func (quadletRenderer) Render(set *ContainerSet) (map[string]string, error) {
+	files := map[string]string{}
+
+	for _, spec := range set.Containers {
+		files[spec.Name+".container"] = quadletContainerUnit(spec)
+	}
+
+	for name := range quadletNamedVolumes(set) {
+		files[name+".volume"] = quadletVolumeUnit(name)
+	}
+
+	var networkNames []string
+	for name := range set.Networks {
+		networkNames = append(networkNames, name)
+	}
+	sort.Strings(networkNames)
+	for _, name := range networkNames {
+		files[name+".network"] = quadletNetworkUnit(set.Networks[name])
+	}
+
+	return files, nil
+}
+
+// quadletContainerUnit renders a single .container unit.
+func quadletContainerUnit(spec ContainerSpec) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n\n", spec.Name)
+
+	fmt.Fprintf(&b, "[Container]\n")
+	fmt.Fprintf(&b, "Image=%s\n", spec.Image)
+	fmt.Fprintf(&b, "ContainerName=%s\n", spec.Name)
+
+	if spec.Hostname != "" {
+		fmt.Fprintf(&b, "HostName=%s\n", spec.Hostname)
+	}
+	if len(spec.Entrypoint) > 0 {
+		fmt.Fprintf(&b, "Entrypoint=%s\n", strings.Join(spec.Entrypoint, " "))
+	}
+	if len(spec.Command) > 0 {
+		fmt.Fprintf(&b, "Exec=%s\n", strings.Join(spec.Command, " "))
+	}
+	for _, e := range spec.Env {
+		fmt.Fprintf(&b, "Environment=%s\n", e)
+	}
+	for _, p := range spec.Ports {
+		hostPort := p.HostPort
+		if p.HostIP != "" {
+			hostPort = p.HostIP + ":" + hostPort
+		}
+		containerPort := p.ContainerPort
+		if p.Protocol != "" && p.Protocol != "tcp" {
+			containerPort += "/" + p.Protocol
+		}
+		fmt.Fprintf(&b, "PublishPort=%s:%s\n", hostPort, containerPort)
+	}
+	for _, v := range spec.Volumes {
+		if v.Type == "volume" {
+			fmt.Fprintf(&b, "Volume=%s.volume:%s\n", v.Source, v.Destination)
+		} else {
+			fmt.Fprintf(&b, "Volume=%s:%s\n", v.Source, v.Destination)
+		}
+	}
+	if spec.NetworkMode != "" && spec.NetworkMode != "default" {
+		fmt.Fprintf(&b, "Network=%s.network\n", spec.NetworkMode)
+	}
+	if spec.Privileged {
+		fmt.Fprintf(&b, "PodmanArgs=--privileged\n")
+	}
+
+	fmt.Fprintf(&b, "\n[Service]\n")
+	fmt.Fprintf(&b, "Restart=%s\n", quadletRestart(spec.RestartPolicy))
+
+	fmt.Fprintf(&b, "\n[Install]\n")
+	fmt.Fprintf(&b, "WantedBy=multi-user.target default.target\n")
+
+	return b.String()
+}
+
+// quadletRestart maps a Docker restart policy name onto the systemd
+// Restart= values Quadlet expects.
+func quadletRestart(policy string) string {
+	switch policy {
+	case "always", "unless-stopped":
+		return "always"
+	case "on-failure":
+		return "on-failure"
+	default:
+		return "no"
+	}
+}
+
+func quadletVolumeUnit(name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s volume\n\n", name)
+	fmt.Fprintf(&b, "[Volume]\n")
+	return b.String()
+}
+
+func quadletNetworkUnit(net NetworkSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s network\n\n", net.Name)
+	fmt.Fprintf(&b, "[Network]\n")
+	return b.String()
+}
+
+// quadletNamedVolumes collects every distinct named ("volume" type)
+// mount across the whole container set.
+func quadletNamedVolumes(set *ContainerSet) map[string]struct{} {
+	names := map[string]struct{}{}
+	for _, spec := range set.Containers {
+		for _, v := range spec.Volumes {
+			if v.Type == "volume" {
+				names[v.Source] = struct{}{}
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,98 @@
+package composegen
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	composeProjectLabel    = "com.docker.compose.project"
+	composeServiceLabel    = "com.docker.compose.service"
+	composeConfigHashLabel = "com.docker.compose.config-hash"
+)
+
+// defaultProject is the bucket containers with no
+// com.docker.compose.project label are grouped and filtered under.
+const defaultProject = "default"
+
+// projectBucket is the project group a container's output is keyed
+// and filtered under: its compose project label, or defaultProject
+// when it has none. groupByProject and filterByProject must agree on
+// this so e.g. --project default selects the same containers that
+// end up in the "default" output file.
+func projectBucket(spec ContainerSpec) string {
+	if spec.Project == "" {
+		return defaultProject
+	}
+	return spec.Project
+}
+
+// groupByProject splits a ContainerSet into one ContainerSet per
+// projectBucket, so each group can be rendered as its own compose
+// file. Each bucket's Networks is scoped to only the networks its own
+// containers reference, rather than set.Networks as a whole, so one
+// project's compose file doesn't list every other project's networks.
+func groupByProject(set *ContainerSet) map[string]*ContainerSet {
+	projects := map[string]*ContainerSet{}
+	for _, spec := range set.Containers {
+		project := projectBucket(spec)
+
+		ps, ok := projects[project]
+		if !ok {
+			ps = &ContainerSet{Networks: map[string]NetworkSpec{}}
+			projects[project] = ps
+		}
+		ps.Containers = append(ps.Containers, spec)
+
+		for _, netName := range spec.NetworkNames {
+			if netSpec, ok := set.Networks[netName]; ok {
+				ps.Networks[netName] = netSpec
+			}
+		}
+	}
+	return projects
+}
+
+// filterByProject drops containers whose projectBucket doesn't match
+// projectFilter, mirroring filterContainerNames's --filter handling
+// for the --project flag.
+func filterByProject(containers []ContainerSpec, projectFilter string) ([]ContainerSpec, error) {
+	if projectFilter == "" {
+		return containers, nil
+	}
+
+	re, err := regexp.Compile(projectFilter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project filter regex: %v", err)
+	}
+
+	filtered := make([]ContainerSpec, 0, len(containers))
+	for _, spec := range containers {
+		if re.MatchString(projectBucket(spec)) {
+			filtered = append(filtered, spec)
+		}
+	}
+	return filtered, nil
+}
+
+// composeServiceKey is the key a ContainerSpec renders under in a
+// compose services block: the compose-derived service name when
+// known, the bare container name otherwise.
+func composeServiceKey(spec ContainerSpec) string {
+	if spec.ServiceName != "" {
+		return spec.ServiceName
+	}
+	return spec.Name
+}
+
+// isDefaultComposeContainerName reports whether spec.Name is exactly
+// what compose itself generates for this project/service pair
+// (<project>_<service>_1, or <project>-<service>-1 for the v2 CLI),
+// in which case container_name is redundant and should be omitted.
+func isDefaultComposeContainerName(spec ContainerSpec) bool {
+	if spec.Project == "" || spec.ServiceName == "" {
+		return false
+	}
+	return spec.Name == fmt.Sprintf("%s_%s_1", spec.Project, spec.ServiceName) ||
+		spec.Name == fmt.Sprintf("%s-%s-1", spec.Project, spec.ServiceName)
+}
@@ -0,0 +1,59 @@
+package composegen
+
+import "testing"
+
+func TestProjectBucket(t *testing.T) {
+	tests := []struct {
+		name string
+		spec ContainerSpec
+		want string
+	}{
+		{"labeled project", ContainerSpec{Project: "myapp"}, "myapp"},
+		{"no project label", ContainerSpec{Project: ""}, defaultProject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := projectBucket(tt.spec); got != tt.want {
+				t.Errorf("projectBucket(%+v) = %q, want %q", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDefaultComposeContainerName(t *testing.T) {
+	tests := []struct {
+		name string
+		spec ContainerSpec
+		want bool
+	}{
+		{
+			name: "v1 style name",
+			spec: ContainerSpec{Project: "myapp", ServiceName: "web", Name: "myapp_web_1"},
+			want: true,
+		},
+		{
+			name: "v2 style name",
+			spec: ContainerSpec{Project: "myapp", ServiceName: "web", Name: "myapp-web-1"},
+			want: true,
+		},
+		{
+			name: "custom container_name",
+			spec: ContainerSpec{Project: "myapp", ServiceName: "web", Name: "my-custom-name"},
+			want: false,
+		},
+		{
+			name: "no compose labels at all",
+			spec: ContainerSpec{Name: "standalone"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDefaultComposeContainerName(tt.spec); got != tt.want {
+				t.Errorf("isDefaultComposeContainerName(%+v) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package composegen
+
+import "fmt"
+
+// Renderer turns a ContainerSet into one or more output files, keyed
+// by file name relative to the output directory. compose renders one
+// YAML document per project, keyed by project name; kube and quadlet
+// each render several files (a manifest set / one unit per resource).
+type Renderer interface {
+	Render(set *ContainerSet) (map[string]string, error)
+}
+
+// RendererFor resolves the Renderer backend selected on the CLI via
+// --format compose|kube|quadlet. It defaults to compose so existing
+// callers don't need to change.
+func RendererFor(format string) (Renderer, error) {
+	switch format {
+	case "", "compose":
+		return composeRenderer{}, nil
+	case "kube":
+		return kubeRenderer{}, nil
+	case "quadlet":
+		return quadletRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown renderer format: %s", format)
+	}
+}
@@ -0,0 +1,227 @@
+package composegen
+
+import (
+	"fmt"
+	"sort"
+)
+
+// composeRenderer is the original backend: one compose file per
+// com.docker.compose.project group found in the ContainerSet (or a
+// single "default" file when none of the containers carry that
+// label), keyed by project name.
+type composeRenderer struct{}
+
+func (composeRenderer) Render(set *ContainerSet) (map[string]string, error) {
+	files := map[string]string{}
+	for project, projectSet := range groupByProject(set) {
+		out, err := render(buildComposeConfig(projectSet))
+		if err != nil {
+			return nil, err
+		}
+		files[project] = out
+	}
+	return files, nil
+}
+
+// buildComposeConfig converts a ContainerSet into the compose Config
+// shape, dropping ignored values from each service via
+// composeServiceValues.
+func buildComposeConfig(set *ContainerSet) Config {
+	config := buildComposeConfigSkeleton(set)
+
+	services := map[string]map[string]interface{}{}
+	for _, spec := range set.Containers {
+		services[composeServiceKey(spec)] = composeServiceValues(spec)
+	}
+	config.Services = services
+
+	return config
+}
+
+// buildComposeConfigSkeleton fills in everything but Services: the
+// Version and Networks common to every backend that builds its own
+// services map (e.g. Generator, which runs Transformers over each
+// service instead of composeServiceValues).
+func buildComposeConfigSkeleton(set *ContainerSet) Config {
+	networks := map[string]map[string]interface{}{}
+	for name, netSpec := range set.Networks {
+		networks[name] = map[string]interface{}{
+			"external": netSpec.External,
+			"name":     netSpec.Name,
+		}
+	}
+
+	return Config{
+		Version:  "3.6",
+		Networks: networks,
+	}
+}
+
+// composeServiceValues renders a single ContainerSpec's fields into
+// the compose key/value shape, dropping anything isIgnoredValue
+// considers a default.
+func composeServiceValues(spec ContainerSpec) map[string]interface{} {
+	ct := map[string]interface{}{}
+	for key, value := range rawComposeValues(spec) {
+		if !isIgnoredValue(value) {
+			ct[key] = value
+		}
+	}
+	appendComposeExtras(ct, spec)
+	return ct
+}
+
+// rawComposeValues builds the base compose key/value map for a
+// service, before isIgnoredValue filtering or Transformers run. Keep
+// this (rather than composeServiceValues) as the extension point for
+// anything that needs to see default values before they're dropped.
+func rawComposeValues(spec ContainerSpec) map[string]interface{} {
+	containerName := spec.Name
+	if isDefaultComposeContainerName(spec) {
+		containerName = ""
+	}
+
+	return map[string]interface{}{
+		"container_name": containerName,
+		"image":          spec.Image,
+		"labels":         spec.Labels,
+		"volumes":        formatComposeVolumes(spec.Volumes),
+		"environment":    spec.Env,
+		"command":        spec.Command,
+		"entrypoint":     spec.Entrypoint,
+		"working_dir":    spec.WorkingDir,
+		"user":           spec.User,
+		"hostname":       spec.Hostname,
+		"domainname":     spec.Domainname,
+		"network_mode":   spec.NetworkMode,
+		"ports":          formatComposePorts(spec.Ports),
+		"privileged":     spec.Privileged,
+		"restart":        spec.RestartPolicy,
+		"tty":            spec.TTY,
+		"stdin_open":     spec.StdinOpen,
+	}
+}
+
+// appendComposeExtras adds the fields that are only ever set when
+// non-empty: being slices and maps of concrete types, they don't
+// reliably match the generic isIgnoredValue filter.
+func appendComposeExtras(ct map[string]interface{}, spec ContainerSpec) {
+	if spec.Healthcheck != nil {
+		ct["healthcheck"] = formatComposeHealthcheck(*spec.Healthcheck)
+	}
+	if len(spec.DependsOn) > 0 {
+		ct["depends_on"] = spec.DependsOn
+	}
+	if len(spec.Ulimits) > 0 {
+		ct["ulimits"] = formatComposeUlimits(spec.Ulimits)
+	}
+	if len(spec.Sysctls) > 0 {
+		ct["sysctls"] = spec.Sysctls
+	}
+	if len(spec.CapAdd) > 0 {
+		ct["cap_add"] = spec.CapAdd
+	}
+	if len(spec.CapDrop) > 0 {
+		ct["cap_drop"] = spec.CapDrop
+	}
+	if len(spec.Devices) > 0 {
+		ct["devices"] = formatComposeDevices(spec.Devices)
+	}
+	if spec.Logging != nil {
+		ct["logging"] = formatComposeLogging(*spec.Logging)
+	}
+	if len(spec.SecurityOpt) > 0 {
+		ct["security_opt"] = spec.SecurityOpt
+	}
+	if len(spec.Tmpfs) > 0 {
+		ct["tmpfs"] = spec.Tmpfs
+	}
+}
+
+// formatComposeHealthcheck renders a Healthcheck as compose's
+// healthcheck block.
+func formatComposeHealthcheck(hc Healthcheck) map[string]interface{} {
+	h := map[string]interface{}{"test": hc.Test}
+	if hc.Interval > 0 {
+		h["interval"] = hc.Interval.String()
+	}
+	if hc.Timeout > 0 {
+		h["timeout"] = hc.Timeout.String()
+	}
+	if hc.Retries > 0 {
+		h["retries"] = hc.Retries
+	}
+	if hc.StartPeriod > 0 {
+		h["start_period"] = hc.StartPeriod.String()
+	}
+	return h
+}
+
+// formatComposeUlimits renders Ulimits keyed by name, using the plain
+// integer short form when soft and hard match.
+func formatComposeUlimits(ulimits []Ulimit) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, u := range ulimits {
+		if u.Soft == u.Hard {
+			out[u.Name] = u.Soft
+			continue
+		}
+		out[u.Name] = map[string]interface{}{"soft": u.Soft, "hard": u.Hard}
+	}
+	return out
+}
+
+// formatComposeDevices renders DeviceMappings as compose's
+// "host:container[:permissions]" short syntax.
+func formatComposeDevices(devices []DeviceMapping) []string {
+	var out []string
+	for _, d := range devices {
+		entry := fmt.Sprintf("%s:%s", d.PathOnHost, d.PathInContainer)
+		if d.CgroupPermissions != "" {
+			entry += ":" + d.CgroupPermissions
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// formatComposeLogging renders a LoggingConfig as compose's logging
+// block.
+func formatComposeLogging(logging LoggingConfig) map[string]interface{} {
+	l := map[string]interface{}{"driver": logging.Driver}
+	if len(logging.Options) > 0 {
+		l["options"] = logging.Options
+	}
+	return l
+}
+
+// formatComposeVolumes renders normalized mounts as compose's
+// "source:destination" short syntax, sorted for stable output.
+func formatComposeVolumes(mounts []VolumeMount) []string {
+	var volumes []string
+	for _, m := range mounts {
+		volumes = append(volumes, fmt.Sprintf("%s:%s", m.Source, m.Destination))
+	}
+	sort.Strings(volumes)
+	return volumes
+}
+
+// formatComposePorts renders normalized ports as compose's
+// "hostIP:hostPort:containerPort[/protocol]" short syntax, omitting
+// the protocol suffix for the default "tcp" the way compose itself
+// does.
+func formatComposePorts(mappings []PortMapping) []string {
+	var ports []string
+	for _, p := range mappings {
+		hostPort := p.HostPort
+		if p.HostIP != "" {
+			hostPort = p.HostIP + ":" + hostPort
+		}
+		entry := fmt.Sprintf("%s:%s", hostPort, p.ContainerPort)
+		if p.Protocol != "" && p.Protocol != "tcp" {
+			entry += "/" + p.Protocol
+		}
+		ports = append(ports, entry)
+	}
+	return ports
+}
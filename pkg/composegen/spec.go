@@ -0,0 +1,305 @@
+package composegen
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// ContainerSpec is a normalized, format-agnostic view of an inspected
+// container. It is the common input every Renderer backend works
+// from, so adding a new output format never requires touching the
+// Docker inspection code again.
+type ContainerSpec struct {
+	Name          string
+	Image         string
+	Labels        map[string]string
+	Env           []string
+	Command       []string
+	Entrypoint    []string
+	WorkingDir    string
+	User          string
+	Hostname      string
+	Domainname    string
+	NetworkMode   string
+	NetworkNames  []string
+	Ports         []PortMapping
+	Volumes       []VolumeMount
+	Privileged    bool
+	RestartPolicy string
+	TTY           bool
+	StdinOpen     bool
+	Healthcheck   *Healthcheck
+	DependsOn     []string
+	Ulimits       []Ulimit
+	Sysctls       map[string]string
+	CapAdd        []string
+	CapDrop       []string
+	Devices       []DeviceMapping
+	Logging       *LoggingConfig
+	SecurityOpt   []string
+	Tmpfs         []string
+
+	// Project, ServiceName and ConfigHash come from the standard
+	// com.docker.compose.* labels compose itself sets, letting
+	// GenerateComposeFile re-derive the project that provisioned a
+	// container rather than treating every container as standalone.
+	Project     string
+	ServiceName string
+	ConfigHash  string
+}
+
+// Healthcheck is normalized from Config.Healthcheck.
+type Healthcheck struct {
+	Test        []string
+	Interval    time.Duration
+	Timeout     time.Duration
+	Retries     int
+	StartPeriod time.Duration
+}
+
+// Ulimit is a single resource limit, normalized from
+// HostConfig.Ulimits.
+type Ulimit struct {
+	Name string
+	Soft int64
+	Hard int64
+}
+
+// DeviceMapping is a single host device passed through to the
+// container, normalized from HostConfig.Devices.
+type DeviceMapping struct {
+	PathOnHost        string
+	PathInContainer   string
+	CgroupPermissions string
+}
+
+// LoggingConfig is normalized from HostConfig.LogConfig.
+type LoggingConfig struct {
+	Driver  string
+	Options map[string]string
+}
+
+// PortMapping is a single published port, normalized from
+// HostConfig.PortBindings.
+type PortMapping struct {
+	HostIP        string
+	HostPort      string
+	ContainerPort string
+	Protocol      string
+}
+
+// VolumeMount is a single mount point, normalized from
+// ContainerJSON.Mounts.
+type VolumeMount struct {
+	Type        string // "volume" or "bind"
+	Source      string
+	Destination string
+}
+
+// NetworkSpec is a normalized view of an inspected Docker network.
+type NetworkSpec struct {
+	Name     string
+	External bool
+}
+
+// ContainerSet is the full collection of inspected containers and
+// networks a Renderer turns into output files.
+type ContainerSet struct {
+	Containers []ContainerSpec
+	Networks   map[string]NetworkSpec
+}
+
+// findContainerID resolves a container name or ID to its full ID. It
+// is used by inspectContainer so there is a single place that knows
+// how container lookups work.
+func findContainerID(cli *client.Client, cname string) (string, error) {
+	containers, err := cli.ContainerList(context.Background(), container.ListOptions{All: true})
+	if err != nil {
+		return "", err
+	}
+
+	for _, c := range containers {
+		if c.Names[0][1:] == cname || c.ID == cname {
+			return c.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("container %s not found", cname)
+}
+
+// inspectContainer resolves cname and returns its full inspect output.
+func inspectContainer(cli *client.Client, cname string) (types.ContainerJSON, error) {
+	containerID, err := findContainerID(cli, cname)
+	if err != nil {
+		return types.ContainerJSON{}, err
+	}
+	return cli.ContainerInspect(context.Background(), containerID)
+}
+
+// buildContainerSpec normalizes an inspected container into a
+// ContainerSpec, independent of any particular output format.
+func buildContainerSpec(cattrs types.ContainerJSON, opts Options) ContainerSpec {
+	return ContainerSpec{
+		Name:          cattrs.Name[1:],
+		Image:         cattrs.Config.Image,
+		Labels:        cattrs.Config.Labels,
+		Env:           cattrs.Config.Env,
+		Command:       cattrs.Config.Cmd,
+		Entrypoint:    cattrs.Config.Entrypoint,
+		WorkingDir:    cattrs.Config.WorkingDir,
+		User:          cattrs.Config.User,
+		Hostname:      cattrs.Config.Hostname,
+		Domainname:    cattrs.Config.Domainname,
+		NetworkMode:   getNetworkMode(cattrs),
+		NetworkNames:  getNetworkNames(cattrs),
+		Ports:         getPortMappings(cattrs),
+		Volumes:       getVolumeMounts(cattrs, opts.CreateVolumes),
+		Privileged:    cattrs.HostConfig.Privileged,
+		RestartPolicy: string(cattrs.HostConfig.RestartPolicy.Name),
+		TTY:           cattrs.Config.Tty,
+		StdinOpen:     cattrs.Config.OpenStdin,
+		Healthcheck:   getHealthcheck(cattrs),
+		DependsOn:     getDependsOn(cattrs),
+		Ulimits:       getUlimits(cattrs),
+		Sysctls:       cattrs.HostConfig.Sysctls,
+		CapAdd:        cattrs.HostConfig.CapAdd,
+		CapDrop:       cattrs.HostConfig.CapDrop,
+		Devices:       getDevices(cattrs),
+		Logging:       getLogging(cattrs),
+		SecurityOpt:   cattrs.HostConfig.SecurityOpt,
+		Tmpfs:         getTmpfs(cattrs),
+		Project:       cattrs.Config.Labels[composeProjectLabel],
+		ServiceName:   cattrs.Config.Labels[composeServiceLabel],
+		ConfigHash:    cattrs.Config.Labels[composeConfigHashLabel],
+	}
+}
+
+// getNetworkNames lists the networks a container is attached to, sorted
+// for stable output. groupByProject uses this to scope a project's
+// Networks down to only the networks its own containers reference.
+func getNetworkNames(cattrs types.ContainerJSON) []string {
+	var names []string
+	for name := range cattrs.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// buildNetworkSpecs inspects every network a container is attached to
+// and normalizes them, shared by the compose and non-compose
+// renderers alike.
+func buildNetworkSpecs(cli *client.Client, cattrs types.ContainerJSON) map[string]NetworkSpec {
+	networks := map[string]NetworkSpec{}
+	for networkName := range cattrs.NetworkSettings.Networks {
+		networkResource, err := cli.NetworkInspect(context.Background(), networkName, types.NetworkInspectOptions{})
+		if err != nil {
+			log.Printf("Error inspecting network %s: %v", networkName, err)
+			continue
+		}
+
+		networks[networkName] = NetworkSpec{
+			Name:     networkName,
+			External: !networkResource.Internal,
+		}
+	}
+	return networks
+}
+
+// getPortMappings normalizes HostConfig.PortBindings into
+// PortMapping values.
+func getPortMappings(cattrs types.ContainerJSON) []PortMapping {
+	var ports []PortMapping
+	for port, bindings := range cattrs.HostConfig.PortBindings {
+		for _, binding := range bindings {
+			ports = append(ports, PortMapping{
+				HostIP:        binding.HostIP,
+				HostPort:      binding.HostPort,
+				ContainerPort: port.Port(),
+				Protocol:      port.Proto(),
+			})
+		}
+	}
+	return ports
+}
+
+// getVolumeMounts normalizes ContainerJSON.Mounts into VolumeMount
+// values.
+func getVolumeMounts(cattrs types.ContainerJSON, createVolumes bool) []VolumeMount {
+	var volumes []VolumeMount
+	for _, mount := range cattrs.Mounts {
+		switch {
+		case mount.Type == "volume" && createVolumes:
+			volumes = append(volumes, VolumeMount{Type: "volume", Source: mount.Name, Destination: mount.Destination})
+		case mount.Type == "bind":
+			volumes = append(volumes, VolumeMount{Type: "bind", Source: mount.Source, Destination: mount.Destination})
+		}
+	}
+	return volumes
+}
+
+// GenerateContainerSet inspects every container selected by opts and
+// returns the normalized ContainerSet that all Renderer backends
+// consume.
+func GenerateContainerSet(cli *client.Client, opts Options) (*ContainerSet, error) {
+	containerNames, err := listContainerNames(cli)
+	if err != nil {
+		return nil, err
+	}
+
+	containerNames, err = filterContainerNames(containerNames, opts.ContainerFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &ContainerSet{Networks: map[string]NetworkSpec{}}
+	for _, cname := range containerNames {
+		cattrs, err := inspectContainer(cli, cname)
+		if err != nil {
+			log.Printf("Error inspecting container %s: %v", cname, err)
+			continue
+		}
+
+		set.Containers = append(set.Containers, buildContainerSpec(cattrs, opts))
+		for name, spec := range buildNetworkSpecs(cli, cattrs) {
+			set.Networks[name] = spec
+		}
+	}
+
+	containers, err := filterByProject(set.Containers, opts.ProjectFilter)
+	if err != nil {
+		return nil, err
+	}
+	set.Containers = containers
+
+	return set, nil
+}
+
+// filterContainerNames applies the optional --filter regex, shared by
+// every Generate* entry point.
+func filterContainerNames(containerNames []string, containerFilter string) ([]string, error) {
+	if containerFilter == "" {
+		return containerNames, nil
+	}
+
+	filterRegex, err := regexp.Compile(containerFilter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter regex: %v", err)
+	}
+
+	filtered := []string{}
+	for _, name := range containerNames {
+		if filterRegex.MatchString(name) {
+			filtered = append(filtered, name)
+		}
+	}
+	return filtered, nil
+}
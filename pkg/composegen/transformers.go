@@ -0,0 +1,179 @@
+package composegen
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/client"
+)
+
+// SecretRedactor replaces environment variables whose name matches
+// any of the patterns given to NewSecretRedactor with a "${VAR}"
+// placeholder, and collects the
+// original values so they can be written to a companion .env file
+// instead of the compose YAML.
+type SecretRedactor struct {
+	patterns []*regexp.Regexp
+	secrets  map[string]string
+}
+
+// NewSecretRedactor compiles patterns (e.g. `(?i)(password|token|secret|key)`)
+// matched against environment variable names.
+func NewSecretRedactor(patterns ...string) (*SecretRedactor, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret pattern %q: %v", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return &SecretRedactor{patterns: compiled, secrets: map[string]string{}}, nil
+}
+
+func (r *SecretRedactor) Transform(service string, key string, value interface{}) (interface{}, bool) {
+	if key != "environment" {
+		return value, true
+	}
+
+	env, ok := value.([]string)
+	if !ok {
+		return value, true
+	}
+
+	redacted := make([]string, len(env))
+	for i, e := range env {
+		name, val, found := strings.Cut(e, "=")
+		if !found || !r.matches(name) {
+			redacted[i] = e
+			continue
+		}
+		r.secrets[name] = val
+		redacted[i] = fmt.Sprintf("%s=${%s}", name, name)
+	}
+	return redacted, true
+}
+
+func (r *SecretRedactor) matches(name string) bool {
+	for _, re := range r.patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Files emits the redacted values as a .env file, which compose loads
+// automatically to resolve the "${VAR}" placeholders left in
+// docker-compose.yml.
+func (r *SecretRedactor) Files() map[string]string {
+	if len(r.secrets) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(r.secrets))
+	for name := range r.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%s\n", name, r.secrets[name])
+	}
+	return map[string]string{".env": b.String()}
+}
+
+// PathRewriter rewrites bind-mount host paths that start with one of
+// Prefixes' keys to start with its value instead, so generated
+// compose files stay portable across hosts (e.g. rewriting
+// "/var/lib/docker/volumes" to "./volumes").
+type PathRewriter struct {
+	Prefixes map[string]string
+}
+
+func (p PathRewriter) Transform(service string, key string, value interface{}) (interface{}, bool) {
+	if key != "volumes" {
+		return value, true
+	}
+
+	vols, ok := value.([]string)
+	if !ok {
+		return value, true
+	}
+
+	rewritten := make([]string, len(vols))
+	for i, v := range vols {
+		rewritten[i] = p.rewrite(v)
+	}
+	return rewritten, true
+}
+
+func (p PathRewriter) rewrite(volume string) string {
+	source, rest, found := strings.Cut(volume, ":")
+	if !found {
+		return volume
+	}
+
+	// Longest matching prefix wins, so a more specific override takes
+	// precedence over a broader one covering the same path.
+	var bestOld, bestNew string
+	for oldPrefix, newPrefix := range p.Prefixes {
+		if strings.HasPrefix(source, oldPrefix) && len(oldPrefix) > len(bestOld) {
+			bestOld, bestNew = oldPrefix, newPrefix
+		}
+	}
+	if bestOld == "" {
+		return volume
+	}
+	return bestNew + strings.TrimPrefix(source, bestOld) + ":" + rest
+}
+
+// ImageDigestPinner rewrites each service's image: to its resolved
+// repo@sha256 digest, so a generated compose file always pulls the
+// exact image it was generated from. Build Digests with
+// ResolveImageDigests.
+type ImageDigestPinner struct {
+	Digests map[string]string // image ref -> repo@sha256:... digest
+}
+
+func (p ImageDigestPinner) Transform(service string, key string, value interface{}) (interface{}, bool) {
+	if key != "image" {
+		return value, true
+	}
+
+	image, ok := value.(string)
+	if !ok {
+		return value, true
+	}
+
+	if digest, ok := p.Digests[image]; ok {
+		return digest, true
+	}
+	return value, true
+}
+
+// ResolveImageDigests inspects every distinct image referenced by
+// set's containers and returns a map from image ref to its first
+// RepoDigests entry, for use with ImageDigestPinner. Images with no
+// RepoDigests (e.g. built locally and never pushed) are omitted.
+func ResolveImageDigests(cli *client.Client, set *ContainerSet) (map[string]string, error) {
+	digests := map[string]string{}
+	for _, spec := range set.Containers {
+		if _, done := digests[spec.Image]; done {
+			continue
+		}
+
+		inspect, _, err := cli.ImageInspectWithRaw(context.Background(), spec.Image)
+		if err != nil {
+			return nil, fmt.Errorf("error inspecting image %s: %v", spec.Image, err)
+		}
+		if len(inspect.RepoDigests) > 0 {
+			digests[spec.Image] = inspect.RepoDigests[0]
+		}
+	}
+	return digests, nil
+}
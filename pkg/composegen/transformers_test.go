@@ -0,0 +1,69 @@
+package composegen
+
+import "testing"
+
+func TestSecretRedactorMatches(t *testing.T) {
+	r, err := NewSecretRedactor(`(?i)(password|token|secret)`)
+	if err != nil {
+		t.Fatalf("NewSecretRedactor() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"DB_PASSWORD", true},
+		{"API_TOKEN", true},
+		{"aws_secret_key", true},
+		{"DB_HOST", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := r.matches(tt.name); got != tt.want {
+			t.Errorf("matches(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPathRewriterRewrite(t *testing.T) {
+	p := PathRewriter{Prefixes: map[string]string{
+		"/var/lib/docker/volumes":          "./volumes",
+		"/var/lib/docker/volumes/app-data": "./app-data",
+	}}
+
+	tests := []struct {
+		name   string
+		volume string
+		want   string
+	}{
+		{
+			name:   "longest prefix wins",
+			volume: "/var/lib/docker/volumes/app-data/_data:/data",
+			want:   "./app-data/_data:/data",
+		},
+		{
+			name:   "falls back to shorter prefix",
+			volume: "/var/lib/docker/volumes/other/_data:/data",
+			want:   "./volumes/other/_data:/data",
+		},
+		{
+			name:   "no matching prefix is unchanged",
+			volume: "/srv/app:/data",
+			want:   "/srv/app:/data",
+		},
+		{
+			name:   "no destination is unchanged",
+			volume: "named-volume",
+			want:   "named-volume",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.rewrite(tt.volume); got != tt.want {
+				t.Errorf("rewrite(%q) = %q, want %q", tt.volume, got, tt.want)
+			}
+		})
+	}
+}
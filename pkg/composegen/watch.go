@@ -0,0 +1,193 @@
+package composegen
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/client"
+)
+
+// debounceWindow coalesces bursts of Docker events (e.g. a `compose
+// up` creating a dozen containers at once) into a single regenerate.
+const debounceWindow = 500 * time.Millisecond
+
+// Snapshot is a single regenerated compose document emitted by Watch,
+// along with the names of the containers, networks and volumes that
+// Docker events reported since the previous Snapshot.
+type Snapshot struct {
+	YAML    string
+	Changed []string
+}
+
+// Watch subscribes to the Docker events stream and sends a refreshed
+// Snapshot to sink every time a container, network or volume is
+// created, destroyed, renamed or has its config changed. Events are
+// debounced by debounceWindow so a burst only triggers one
+// regenerate. Only containers named by the events since the last
+// Snapshot are re-inspected; everything else is served from an
+// in-memory cache. Watch blocks until ctx is cancelled or the events
+// stream errors.
+func Watch(ctx context.Context, cli *client.Client, opts Options, sink chan<- Snapshot) error {
+	msgs, errs := cli.Events(ctx, events.ListOptions{})
+
+	w := &watcher{cli: cli, opts: opts, cache: map[string]ContainerSpec{}, networks: map[string]map[string]NetworkSpec{}}
+	dirty := map[string]struct{}{}
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case err := <-errs:
+			return err
+
+		case msg, ok := <-msgs:
+			if !ok {
+				return nil
+			}
+			if name, ok := eventSubjectName(msg); ok {
+				dirty[name] = struct{}{}
+				debounce = time.After(debounceWindow)
+			}
+
+		case <-debounce:
+			debounce = nil
+			changed := make([]string, 0, len(dirty))
+			for name := range dirty {
+				changed = append(changed, name)
+			}
+			sort.Strings(changed)
+			dirty = map[string]struct{}{}
+
+			yamlOut, err := w.regenerate(changed)
+			if err != nil {
+				log.Printf("Error regenerating compose file: %v", err)
+				continue
+			}
+
+			select {
+			case sink <- Snapshot{YAML: yamlOut, Changed: changed}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// watcher holds the cache of inspected containers that lets
+// regenerate skip re-inspecting anything the event stream hasn't
+// marked dirty.
+type watcher struct {
+	cli      *client.Client
+	opts     Options
+	cache    map[string]ContainerSpec          // keyed by container name
+	networks map[string]map[string]NetworkSpec // keyed by container name
+}
+
+// regenerate rebuilds the ContainerSet, re-inspecting only the
+// containers named in changed, and renders it as compose YAML.
+func (w *watcher) regenerate(changed []string) (string, error) {
+	for _, name := range changed {
+		delete(w.cache, name)
+		delete(w.networks, name)
+	}
+
+	names, err := listContainerNames(w.cli)
+	if err != nil {
+		return "", err
+	}
+	names, err = filterContainerNames(names, w.opts.ContainerFilter)
+	if err != nil {
+		return "", err
+	}
+
+	set := &ContainerSet{Networks: map[string]NetworkSpec{}}
+	seen := map[string]struct{}{}
+	for _, name := range names {
+		seen[name] = struct{}{}
+
+		spec, ok := w.cache[name]
+		if !ok {
+			cattrs, err := inspectContainer(w.cli, name)
+			if err != nil {
+				log.Printf("Error inspecting container %s: %v", name, err)
+				continue
+			}
+			spec = buildContainerSpec(cattrs, w.opts)
+			w.cache[name] = spec
+			w.networks[name] = buildNetworkSpecs(w.cli, cattrs)
+		}
+
+		set.Containers = append(set.Containers, spec)
+	}
+
+	for name := range w.cache {
+		if _, ok := seen[name]; !ok {
+			delete(w.cache, name)
+			delete(w.networks, name)
+		}
+	}
+
+	containers, err := filterByProject(set.Containers, w.opts.ProjectFilter)
+	if err != nil {
+		return "", err
+	}
+	set.Containers = containers
+
+	// Merge every still-present container's networks, not just the
+	// ones re-inspected this round, so a cache hit doesn't drop that
+	// container's networks from the Snapshot.
+	for _, nets := range w.networks {
+		for netName, netSpec := range nets {
+			set.Networks[netName] = netSpec
+		}
+	}
+
+	files, err := renderComposeSet(w.cli, w.opts, set)
+	if err != nil {
+		return "", err
+	}
+	return joinComposeFiles(files), nil
+}
+
+// joinComposeFiles concatenates one YAML document per project, in a
+// stable order, as "---"-separated documents. Snapshot carries a
+// single YAML string, so callers that need per-project files should
+// use GenerateComposeFile directly instead of Watch.
+func joinComposeFiles(files map[string]string) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, files[name])
+	}
+	return strings.Join(parts, "---\n")
+}
+
+// eventSubjectName reports the container/network/volume name an event
+// concerns, and whether the event is one Watch should react to at
+// all.
+func eventSubjectName(msg events.Message) (string, bool) {
+	switch msg.Type {
+	case events.ContainerEventType:
+		switch msg.Action {
+		case events.ActionCreate, events.ActionDestroy, events.ActionRename, events.ActionStart, events.ActionStop, events.ActionDie, events.ActionUpdate:
+			return msg.Actor.Attributes["name"], true
+		}
+	case events.NetworkEventType, events.VolumeEventType:
+		switch msg.Action {
+		case events.ActionCreate, events.ActionDestroy:
+			return msg.Actor.Attributes["name"], true
+		}
+	}
+	return "", false
+}